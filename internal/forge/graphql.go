@@ -2,28 +2,51 @@ package forge
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"log"
+	"math/rand"
 	"net/http"
 	"net/http/httputil"
+	"strconv"
+	"time"
 )
 
-// ExecuteGraphQL posts a query+vars to url with Bearer token, returning raw JSON
-func ExecuteGraphQL(url, query string, vars map[string]interface{}, token string, isDebug bool) ([]byte, error) {
+// GraphqlResponse is the parsed shape of a GraphQL HTTP response body
+type GraphqlResponse struct {
+	Data   json.RawMessage `json:"data,omitempty"`
+	Errors []GraphqlError  `json:"errors,omitempty"`
+}
+
+// GraphqlError is a single entry in a GraphQL response's errors array
+type GraphqlError struct {
+	Message    string                 `json:"message"`
+	Path       []interface{}          `json:"path,omitempty"`
+	Extensions map[string]interface{} `json:"extensions,omitempty"`
+}
+
+// ExecuteGraphQL posts a query+vars to url with Bearer token and optional default headers using
+// client, returning the raw JSON response body, the HTTP status code, and the delay requested by
+// a Retry-After header (0 if absent or unparseable).
+func ExecuteGraphQL(ctx context.Context, client *http.Client, url, query string, vars map[string]interface{}, token string, headers map[string]string, isDebug bool) ([]byte, int, time.Duration, error) {
 	payload := GraphqlRequest{Query: query, Variables: vars}
 	body, err := json.Marshal(payload)
 	if err != nil {
-		return nil, fmt.Errorf("marshal GraphQL payload: %w", err)
+		return nil, 0, 0, fmt.Errorf("marshal GraphQL payload: %w", err)
 	}
 
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(body))
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(body))
 	if err != nil {
-		return nil, fmt.Errorf("create request: %w", err)
+		return nil, 0, 0, fmt.Errorf("create request: %w", err)
 	}
 	req.Header.Set("Content-Type", "application/json")
 
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
 	if token != "" {
 		req.Header.Set("Authorization", token)
 	}
@@ -38,15 +61,17 @@ func ExecuteGraphQL(url, query string, vars map[string]interface{}, token string
 		log.Println("-----------------------")
 	}
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("execute request: %w", err)
+		return nil, 0, 0, fmt.Errorf("execute request: %w", err)
 	}
 	defer resp.Body.Close()
 
+	retryAfter := parseRetryAfter(resp.Header.Get("Retry-After"))
+
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("read response: %w", err)
+		return nil, resp.StatusCode, retryAfter, fmt.Errorf("read response: %w", err)
 	}
 
 	if isDebug {
@@ -63,5 +88,113 @@ func ExecuteGraphQL(url, query string, vars map[string]interface{}, token string
 		log.Println("------------------------")
 	}
 
-	return respBody, nil
+	return respBody, resp.StatusCode, retryAfter, nil
+}
+
+// parseRetryAfter interprets a Retry-After header value, which per RFC 9110 is either a number of
+// seconds or an HTTP-date. Returns 0 if value is empty or neither form parses.
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(value); err == nil {
+		if secs < 0 {
+			return 0
+		}
+		return time.Duration(secs) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		if d := time.Until(at); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
+
+// ExecuteGraphQLWithRetry calls ExecuteGraphQL, retrying transient failures according to policy:
+// network errors, policy.RetryableStatusCodes() responses, and - when policy.RetryThrottled is set -
+// GraphQL errors whose extensions.code is "THROTTLED". A Retry-After header on 429/503 responses
+// overrides the computed backoff delay. ctx cancellation aborts a pending backoff immediately.
+func ExecuteGraphQLWithRetry(ctx context.Context, client *http.Client, url, query string, vars map[string]interface{}, token string, headers map[string]string, policy RetryPolicy, isDebug bool) ([]byte, int, error) {
+	maxAttempts := policy.MaxAttemptsOrDefault()
+
+	var body []byte
+	var status int
+	var retryAfter time.Duration
+	var err error
+
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		body, status, retryAfter, err = ExecuteGraphQL(ctx, client, url, query, vars, token, headers, isDebug)
+
+		retryable := err != nil || policy.isRetryableStatus(status) || (policy.RetryThrottled && isThrottledResponse(body))
+		if !retryable || attempt == maxAttempts {
+			return body, status, err
+		}
+
+		delay := retryAfter
+		if delay <= 0 {
+			delay = policy.backoffDelay(attempt)
+		}
+
+		if isDebug {
+			log.Printf("Retrying GraphQL request (attempt %d/%d) after %s", attempt+1, maxAttempts, delay)
+		}
+
+		select {
+		case <-ctx.Done():
+			return body, status, ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+
+	return body, status, err
+}
+
+// isThrottledResponse reports whether body is a GraphQL response whose errors array includes an
+// entry with extensions.code == "THROTTLED"
+func isThrottledResponse(body []byte) bool {
+	var gqlResp GraphqlResponse
+	if json.Unmarshal(body, &gqlResp) != nil {
+		return false
+	}
+	for _, e := range gqlResp.Errors {
+		if code, ok := e.Extensions["code"].(string); ok && code == "THROTTLED" {
+			return true
+		}
+	}
+	return false
+}
+
+// backoffDelay computes the delay to wait after the given 1-indexed attempt fails, i.e. the delay
+// before attempt+1, applying exponential growth capped at MaxDelayDuration and, if Jitter is set,
+// a random factor between 0.5x and 1.5x.
+func (p RetryPolicy) backoffDelay(attempt int) time.Duration {
+	base := p.BaseDelayDuration()
+	maxDelay := p.MaxDelayDuration()
+
+	delay := base
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if delay >= maxDelay {
+			delay = maxDelay
+			break
+		}
+	}
+
+	if p.Jitter {
+		delay = time.Duration(float64(delay) * (0.5 + rand.Float64()))
+	}
+	if delay > maxDelay {
+		delay = maxDelay
+	}
+	return delay
+}
+
+func (p RetryPolicy) isRetryableStatus(status int) bool {
+	for _, s := range p.RetryableStatusCodes() {
+		if s == status {
+			return true
+		}
+	}
+	return false
 }
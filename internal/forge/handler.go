@@ -4,16 +4,21 @@ import (
 	"bytes"
 	"context"
 	"crypto/sha256"
+	"encoding/json"
 	"fmt"
 	"log"
+	"math"
+	"net/http"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"runtime"
 	"strings"
+	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
+	toon "github.com/toon-format/toon-go"
 )
 
 // CtxAuthKey is used as a key for storing auth tokens in context
@@ -24,8 +29,14 @@ func CreateMCPServer(appConfig *AppConfig, version string) (*server.MCPServer, e
 	// Init MCP server
 	srv := server.NewMCPServer(appConfig.Config.Name, version)
 
+	// Tokens are cached across all tools so that tools sharing a backend share a cache entry
+	tokenCache := NewTokenCache(appConfig.Config.TokenTTLDuration())
+
+	// A single HTTP client, bounded by the configured request timeout, is reused by every tool
+	httpClient := &http.Client{Timeout: appConfig.Config.RequestTimeoutDuration()}
+
 	// Discover & register tools
-	if err := RegisterTools(srv, appConfig.Config, appConfig.ConfigDir, appConfig.IsDebug); err != nil {
+	if err := RegisterTools(srv, appConfig.Config, appConfig.ConfigDir, appConfig.IsDebug, tokenCache, httpClient); err != nil {
 		return nil, fmt.Errorf("registering tools: %w", err)
 	}
 
@@ -33,7 +44,7 @@ func CreateMCPServer(appConfig *AppConfig, version string) (*server.MCPServer, e
 }
 
 // RegisterTools discovers and registers all tools from the config directory
-func RegisterTools(srv *server.MCPServer, cfg *ForgeConfig, configDir string, isDebug bool) error {
+func RegisterTools(srv *server.MCPServer, cfg *ForgeConfig, configDir string, isDebug bool, tokenCache *TokenCache, httpClient *http.Client) error {
 	// Discover & register tools
 	files, err := filepath.Glob(filepath.Join(configDir, "*.yaml"))
 	if err != nil {
@@ -47,8 +58,7 @@ func RegisterTools(srv *server.MCPServer, cfg *ForgeConfig, configDir string, is
 
 		tcfg, err := LoadToolConfig(f)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Warning: skipping %s: %v\n", f, err)
-			continue
+			return fmt.Errorf("loading tool config %s: %w", f, err)
 		}
 
 		opts := []mcp.ToolOption{
@@ -72,35 +82,19 @@ func RegisterTools(srv *server.MCPServer, cfg *ForgeConfig, configDir string, is
 			opts = append(opts, mcp.WithOpenWorldHintAnnotation(*tcfg.Annotations.OpenWorldHint))
 		}
 
-		valid := true
 		for _, inp := range tcfg.Inputs {
-			pOpts := []mcp.PropertyOption{mcp.Description(inp.Description)}
-			if inp.Required {
-				pOpts = append(pOpts, mcp.Required())
-			}
-			switch inp.Type {
-			case "string":
-				opts = append(opts, mcp.WithString(inp.Name, pOpts...))
-			case "number":
-				opts = append(opts, mcp.WithNumber(inp.Name, pOpts...))
-			default:
-				fmt.Fprintf(os.Stderr, "Warning: unsupported type %q in %s\n", inp.Type, tcfg.Name)
-				valid = false
-			}
-		}
-		if !valid {
-			continue
+			opts = append(opts, toolInputOption(inp))
 		}
 
 		tool := mcp.NewTool(tcfg.Name, opts...)
-		srv.AddTool(tool, makeHandler(*cfg, *tcfg, isDebug))
+		srv.AddTool(tool, makeHandler(*cfg, *tcfg, isDebug, tokenCache, httpClient))
 	}
 
 	return nil
 }
 
 // makeHandler produces a ToolHandler for the given configs
-func makeHandler(cfg ForgeConfig, tcfg ToolConfig, isDebug bool) server.ToolHandlerFunc {
+func makeHandler(cfg ForgeConfig, tcfg ToolConfig, isDebug bool, tokenCache *TokenCache, httpClient *http.Client) server.ToolHandlerFunc {
 	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		// 1. Gather variables
 		vars := map[string]interface{}{}
@@ -110,90 +104,318 @@ func makeHandler(cfg ForgeConfig, tcfg ToolConfig, isDebug bool) server.ToolHand
 			if !ok && inp.Required {
 				return mcp.NewToolResultError(fmt.Sprintf("missing required argument: %s", inp.Name)), nil
 			}
-			vars[inp.Name] = val
+			if ok {
+				vars[inp.Name] = coerceInputValue(inp, val)
+			}
 		}
 
-		// 2. Get the token
-		token := ""
-		if cfg.TokenCommand != "" {
-			var cmd *exec.Cmd
-			// Use the appropriate shell based on the OS
-			if runtime.GOOS == "windows" {
-				cmd = exec.Command("cmd", "/C", cfg.TokenCommand)
-			} else {
-				// Assume Unix-like shell for macOS, Linux, etc.
-				cmd = exec.Command("sh", "-c", cfg.TokenCommand)
+		// 2. Resolve the backend this tool targets
+		backend := BackendConfig{
+			URL:            cfg.URL,
+			TokenCommand:   cfg.TokenCommand,
+			Env:            cfg.Env,
+			EnvPassthrough: cfg.EnvPassthrough,
+			Headers:        cfg.Headers,
+		}
+		if tcfg.Backend != "" {
+			b, ok := cfg.Backends[tcfg.Backend]
+			if !ok {
+				return mcp.NewToolResultError(fmt.Sprintf("unknown backend %q", tcfg.Backend)), nil
 			}
+			backend = b
+		}
 
-			// Build merged environment: start with os.Environ() if passthrough, else start empty,
-			// then overlay values from cfg.Env to ensure overrides.
-			var envList []string
-			if cfg.EnvPassthrough {
-				envList = os.Environ()
-			} else {
-				envList = []string{}
+		// 3. Get the token
+		token, err := resolveToken(ctx, tokenCache, backend, isDebug)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("token_command failed", err), nil
+		}
+
+		// 4. Call GraphQL, retrying transient failures and, on a 401, invalidating the cached token
+		res, status, err := ExecuteGraphQLWithRetry(ctx, httpClient, backend.URL, tcfg.Query, vars, token, backend.Headers, cfg.Retry, isDebug)
+		if err != nil {
+			// Return error result to MCP instead of terminating
+			return mcp.NewToolResultErrorFromErr("GraphQL execution failed", err), nil
+		}
+		if status == http.StatusUnauthorized && backend.TokenCommand != "" {
+			tokenCache.Invalidate(tokenCacheKey(backend))
+
+			token, err = resolveToken(ctx, tokenCache, backend, isDebug)
+			if err != nil {
+				return mcp.NewToolResultErrorFromErr("token_command failed", err), nil
 			}
 
-			for key, value := range cfg.Env {
-				// Remove any existing entries for this key
-				prefix := key + "="
-				filtered := envList[:0]
-				for _, e := range envList {
-					if !strings.HasPrefix(e, prefix) {
-						filtered = append(filtered, e)
-					}
-				}
-				envList = append(filtered, fmt.Sprintf("%s=%s", key, value))
+			res, _, err = ExecuteGraphQLWithRetry(ctx, httpClient, backend.URL, tcfg.Query, vars, token, backend.Headers, cfg.Retry, isDebug)
+			if err != nil {
+				return mcp.NewToolResultErrorFromErr("GraphQL execution failed", err), nil
 			}
+		}
 
-			cmd.Env = envList
+		// 5. Render the response according to the configured output mode
+		output := tcfg.Output
+		if output == "" {
+			output = cfg.Output
+		}
 
-			if isDebug {
-				log.Printf("Executing token command: %s", cfg.TokenCommand)
-				if len(cmd.Env) > 0 {
-					log.Printf("Environment variables: %v", cmd.Env)
-				}
+		// Parse the GraphQL envelope so a failed query surfaces as a clear tool error instead of
+		// an opaque JSON blob. In raw mode a non-JSON body (e.g. an HTML error page from an
+		// intermediary proxy) is passed through as-is rather than failing the parse.
+		var gqlResp GraphqlResponse
+		if err := json.Unmarshal(res, &gqlResp); err != nil {
+			if output != "json" && output != "toon" {
+				return mcp.NewToolResultText(string(res)), nil
 			}
+			return mcp.NewToolResultErrorFromErr("parsing GraphQL response", err), nil
+		}
+		if len(gqlResp.Errors) > 0 && isEmptyGraphqlData(gqlResp.Data) {
+			return mcp.NewToolResultError(formatGraphqlErrors(gqlResp.Errors)), nil
+		}
 
-			// Only get a token if the command is specified
-			out, err := cmd.Output()
+		var result *mcp.CallToolResult
+		switch output {
+		case "json":
+			result = mcp.NewToolResultText(string(gqlResp.Data))
+		case "toon":
+			var data interface{}
+			if err := json.Unmarshal(gqlResp.Data, &data); err != nil {
+				return mcp.NewToolResultErrorFromErr("parsing GraphQL data payload", err), nil
+			}
+			toonOut, err := toon.Marshal(data)
 			if err != nil {
-				// Include stderr in the error message if available
-				errMsg := "token_command failed"
-				if exitErr, ok := err.(*exec.ExitError); ok {
-					// Combine exit error message and stderr for better context
-					stderr := string(bytes.TrimSpace(exitErr.Stderr))
-					if stderr != "" {
-						errMsg = fmt.Sprintf("%s: %v Stderr: %s", errMsg, exitErr, stderr)
-					} else {
-						errMsg = fmt.Sprintf("%s: %v", errMsg, exitErr)
-					}
-				}
-				// Return nil error for MCP result error
-				return mcp.NewToolResultErrorFromErr(errMsg, err), nil
+				return mcp.NewToolResultErrorFromErr("encoding TOON response", err), nil
 			}
-			token = "Bearer " + string(bytes.TrimSpace(out))
+			result = mcp.NewToolResultText(string(toonOut))
+		default:
+			result = mcp.NewToolResultText(string(res))
+		}
+
+		// A non-fatal errors array alongside data is a partial success; surface it as an annotation
+		// rather than silently dropping it.
+		if len(gqlResp.Errors) > 0 {
+			result.Meta = mcp.NewMetaFromMap(map[string]any{"x-graphql-errors": gqlResp.Errors})
+		}
+
+		return result, nil
+	}
+}
 
-			if isDebug {
-				log.Printf("Obtained token (sha256): %x\n", sha256.Sum256([]byte(token)))
+// formatGraphqlErrors builds a concise, human-readable message from a GraphQL errors array,
+// including each error's path when present.
+func formatGraphqlErrors(errs []GraphqlError) string {
+	msgs := make([]string, 0, len(errs))
+	for _, e := range errs {
+		msg := e.Message
+		if len(e.Path) > 0 {
+			segments := make([]string, len(e.Path))
+			for i, p := range e.Path {
+				segments[i] = fmt.Sprintf("%v", p)
 			}
-		} else {
-			// No token command specified, proceed with pass through token
-			token, _ = ctx.Value(CtxAuthKey{}).(string)
+			msg = fmt.Sprintf("%s (at %s)", msg, strings.Join(segments, "."))
+		}
+		msgs = append(msgs, msg)
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// isEmptyGraphqlData reports whether a GraphQL response's data field is absent or JSON null,
+// which per the GraphQL spec means the request failed outright rather than partially.
+func isEmptyGraphqlData(data json.RawMessage) bool {
+	trimmed := bytes.TrimSpace(data)
+	return len(trimmed) == 0 || string(trimmed) == "null"
+}
+
+// resolveToken returns the bearer token to use for backend: the cached (or freshly run)
+// token_command output, or the pass-through token from ctx when no token_command is configured.
+func resolveToken(ctx context.Context, cache *TokenCache, backend BackendConfig, isDebug bool) (string, error) {
+	if backend.TokenCommand == "" {
+		token, _ := ctx.Value(CtxAuthKey{}).(string)
 
-			if isDebug {
-				log.Printf("Pass through token (sha256): %x\n", sha256.Sum256([]byte(token)))
+		if isDebug {
+			log.Printf("Pass through token (sha256): %x\n", sha256.Sum256([]byte(token)))
+		}
+		return token, nil
+	}
+
+	raw, err := cache.Get(tokenCacheKey(backend), func() (string, time.Duration, error) {
+		return runTokenCommand(backend, isDebug)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	token := "Bearer " + raw
+	if isDebug {
+		log.Printf("Obtained token (sha256): %x\n", sha256.Sum256([]byte(token)))
+	}
+	return token, nil
+}
+
+// runTokenCommand executes backend.TokenCommand and parses its output into a token and TTL
+func runTokenCommand(backend BackendConfig, isDebug bool) (string, time.Duration, error) {
+	var cmd *exec.Cmd
+	// Use the appropriate shell based on the OS
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", backend.TokenCommand)
+	} else {
+		// Assume Unix-like shell for macOS, Linux, etc.
+		cmd = exec.Command("sh", "-c", backend.TokenCommand)
+	}
+
+	// Build merged environment: start with os.Environ() if passthrough, else start empty,
+	// then overlay values from backend.Env to ensure overrides.
+	var envList []string
+	if backend.EnvPassthrough {
+		envList = os.Environ()
+	} else {
+		envList = []string{}
+	}
+
+	for key, value := range backend.Env {
+		// Remove any existing entries for this key
+		prefix := key + "="
+		filtered := envList[:0]
+		for _, e := range envList {
+			if !strings.HasPrefix(e, prefix) {
+				filtered = append(filtered, e)
 			}
 		}
+		envList = append(filtered, fmt.Sprintf("%s=%s", key, value))
+	}
 
-		// 3. Call GraphQL
-		res, err := ExecuteGraphQL(cfg.URL, tcfg.Query, vars, token, isDebug)
-		if err != nil {
-			// Return error result to MCP instead of terminating
-			return mcp.NewToolResultErrorFromErr("GraphQL execution failed", err), nil
+	cmd.Env = envList
+
+	if isDebug {
+		log.Printf("Executing token command: %s", backend.TokenCommand)
+		if len(cmd.Env) > 0 {
+			log.Printf("Environment variables: %v", cmd.Env)
 		}
+	}
+
+	out, err := cmd.Output()
+	if err != nil {
+		// Include stderr in the error message if available
+		errMsg := "token_command failed"
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			// Combine exit error message and stderr for better context
+			stderr := string(bytes.TrimSpace(exitErr.Stderr))
+			if stderr != "" {
+				errMsg = fmt.Sprintf("%s: %v Stderr: %s", errMsg, exitErr, stderr)
+			} else {
+				errMsg = fmt.Sprintf("%s: %v", errMsg, exitErr)
+			}
+		}
+		return "", 0, fmt.Errorf("%s", errMsg)
+	}
+
+	return parseTokenOutput(out)
+}
 
-		// 4. Return raw JSON
-		return mcp.NewToolResultText(string(res)), nil
+// toolInputOption builds the mcp.ToolOption that exposes a single ToolInput on a tool's schema
+func toolInputOption(inp ToolInput) mcp.ToolOption {
+	pOpts := []mcp.PropertyOption{mcp.Description(inp.Description)}
+	if inp.Required {
+		pOpts = append(pOpts, mcp.Required())
+	}
+
+	switch inp.Type {
+	case "number":
+		return mcp.WithNumber(inp.Name, pOpts...)
+	case "boolean":
+		return mcp.WithBoolean(inp.Name, pOpts...)
+	case "enum":
+		pOpts = append(pOpts, mcp.Enum(inp.Enum...))
+		return mcp.WithString(inp.Name, pOpts...)
+	case "array":
+		pOpts = append(pOpts, mcp.Items(toolInputSchema(itemsToolInput(inp.Items))))
+		return mcp.WithArray(inp.Name, pOpts...)
+	case "object":
+		props := map[string]any{}
+		for name, prop := range inp.Properties {
+			props[name] = toolInputSchema(prop)
+		}
+		pOpts = append(pOpts, mcp.Properties(props))
+		return mcp.WithObject(inp.Name, pOpts...)
+	default: // "string"
+		return mcp.WithString(inp.Name, pOpts...)
+	}
+}
+
+// itemsToolInput converts an "array" ToolInput's Items into the ToolInput shape toolInputSchema and
+// coerceInputValue already know how to handle, so array-of-object elements get the same nested
+// JSON-schema properties and coercion as a top-level "object" input.
+func itemsToolInput(items *ToolInputItems) ToolInput {
+	return ToolInput{Type: items.Type, Properties: items.Properties}
+}
+
+// toolInputSchema renders a ToolInput as a JSON Schema fragment, used for the nested properties
+// of an "object" input.
+func toolInputSchema(inp ToolInput) map[string]any {
+	schema := map[string]any{}
+	switch inp.Type {
+	case "number":
+		schema["type"] = "number"
+	case "boolean":
+		schema["type"] = "boolean"
+	case "enum":
+		schema["type"] = "string"
+		schema["enum"] = inp.Enum
+	case "array":
+		schema["type"] = "array"
+		if inp.Items != nil {
+			schema["items"] = toolInputSchema(itemsToolInput(inp.Items))
+		}
+	case "object":
+		schema["type"] = "object"
+		props := map[string]any{}
+		for name, prop := range inp.Properties {
+			props[name] = toolInputSchema(prop)
+		}
+		schema["properties"] = props
+	default: // "string"
+		schema["type"] = "string"
+	}
+	if inp.Description != "" {
+		schema["description"] = inp.Description
+	}
+	return schema
+}
+
+// coerceInputValue converts an argument value parsed by the MCP framework into the shape expected
+// by the GraphQL variables map for inp's declared type: whole-valued numbers become ints rather
+// than floats, and array/object elements are coerced recursively according to their own schema.
+func coerceInputValue(inp ToolInput, val interface{}) interface{} {
+	switch inp.Type {
+	case "number":
+		if f, ok := val.(float64); ok && f == math.Trunc(f) {
+			return int64(f)
+		}
+		return val
+	case "array":
+		arr, ok := val.([]interface{})
+		if !ok || inp.Items == nil {
+			return val
+		}
+		itemInp := itemsToolInput(inp.Items)
+		out := make([]interface{}, len(arr))
+		for i, v := range arr {
+			out[i] = coerceInputValue(itemInp, v)
+		}
+		return out
+	case "object":
+		obj, ok := val.(map[string]interface{})
+		if !ok {
+			return val
+		}
+		out := make(map[string]interface{}, len(obj))
+		for name, v := range obj {
+			if prop, ok := inp.Properties[name]; ok {
+				out[name] = coerceInputValue(prop, v)
+			} else {
+				out[name] = v
+			}
+		}
+		return out
+	default:
+		return val
 	}
 }
@@ -0,0 +1,143 @@
+package forge
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// newTestGraphQLServer returns an httptest server that always responds with body.
+func newTestGraphQLServer(t *testing.T, body string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func callTestHandler(t *testing.T, backendURL, output string) *mcp.CallToolResult {
+	t.Helper()
+	cfg := ForgeConfig{URL: backendURL}
+	tcfg := ToolConfig{Name: "widgets", Query: "query { widgets { id } }", Output: output}
+	handler := makeHandler(cfg, tcfg, false, NewTokenCache(defaultTokenTTL), &http.Client{})
+
+	result, err := handler(context.Background(), mcp.CallToolRequest{})
+	if err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+	return result
+}
+
+func resultText(t *testing.T, result *mcp.CallToolResult) string {
+	t.Helper()
+	if len(result.Content) != 1 {
+		t.Fatalf("expected exactly one content item, got %d", len(result.Content))
+	}
+	text, ok := result.Content[0].(mcp.TextContent)
+	if !ok {
+		t.Fatalf("expected TextContent, got %T", result.Content[0])
+	}
+	return text.Text
+}
+
+func TestMakeHandler_RawOutput(t *testing.T) {
+	srv := newTestGraphQLServer(t, `{"data":{"widgets":[{"id":"1"},{"id":"2"}]}}`)
+
+	result := callTestHandler(t, srv.URL, "raw")
+	if result.IsError {
+		t.Fatalf("unexpected error result: %s", resultText(t, result))
+	}
+	if got := resultText(t, result); got != `{"data":{"widgets":[{"id":"1"},{"id":"2"}]}}` {
+		t.Errorf("raw output = %q, want the verbatim response body", got)
+	}
+}
+
+func TestMakeHandler_JSONOutput(t *testing.T) {
+	srv := newTestGraphQLServer(t, `{"data":{"widget":{"id":"1","name":"Widget"}}}`)
+
+	result := callTestHandler(t, srv.URL, "json")
+	if result.IsError {
+		t.Fatalf("unexpected error result: %s", resultText(t, result))
+	}
+	if got := resultText(t, result); got != `{"widget":{"id":"1","name":"Widget"}}` {
+		t.Errorf("json output = %q, want just the data field", got)
+	}
+}
+
+func TestMakeHandler_ToonOutput_ArrayOfObjects(t *testing.T) {
+	srv := newTestGraphQLServer(t, `{"data":{"widgets":[{"id":"1","name":"A"},{"id":"2","name":"B"}]}}`)
+
+	result := callTestHandler(t, srv.URL, "toon")
+	if result.IsError {
+		t.Fatalf("unexpected error result: %s", resultText(t, result))
+	}
+	got := resultText(t, result)
+	for _, want := range []string{"widgets", "id", "name", "1", "A", "2", "B"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("toon output %q missing %q", got, want)
+		}
+	}
+}
+
+func TestMakeHandler_ToonOutput_ScalarOnly(t *testing.T) {
+	srv := newTestGraphQLServer(t, `{"data":{"count":42}}`)
+
+	result := callTestHandler(t, srv.URL, "toon")
+	if result.IsError {
+		t.Fatalf("unexpected error result: %s", resultText(t, result))
+	}
+	if got := resultText(t, result); !strings.Contains(got, "42") {
+		t.Errorf("toon output = %q, want it to contain the scalar value", got)
+	}
+}
+
+func TestMakeHandler_GraphQLErrorsSurfaceAsToolError(t *testing.T) {
+	srv := newTestGraphQLServer(t, `{"data":null,"errors":[{"message":"widget not found","path":["widget"]}]}`)
+
+	result := callTestHandler(t, srv.URL, "json")
+	if !result.IsError {
+		t.Fatalf("expected an error result, got: %s", resultText(t, result))
+	}
+	if got := resultText(t, result); !strings.Contains(got, "widget not found") {
+		t.Errorf("error result = %q, want it to contain the GraphQL error message", got)
+	}
+}
+
+func TestMakeHandler_PartialErrorsSurfaceAsMeta(t *testing.T) {
+	srv := newTestGraphQLServer(t, `{"data":{"widgets":[{"id":"1"}]},"errors":[{"message":"slow backend"}]}`)
+
+	result := callTestHandler(t, srv.URL, "json")
+	if result.IsError {
+		t.Fatalf("unexpected error result: %s", resultText(t, result))
+	}
+	if result.Meta == nil {
+		t.Fatal("expected Meta to carry the non-fatal GraphQL errors")
+	}
+}
+
+func TestMakeHandler_RawOutput_NonJSONBodyPassesThrough(t *testing.T) {
+	srv := newTestGraphQLServer(t, "<html>502 Bad Gateway</html>")
+
+	result := callTestHandler(t, srv.URL, "raw")
+	if result.IsError {
+		t.Fatalf("unexpected error result: %s", resultText(t, result))
+	}
+	if got := resultText(t, result); got != "<html>502 Bad Gateway</html>" {
+		t.Errorf("raw output = %q, want the non-JSON body passed through verbatim", got)
+	}
+}
+
+func TestMakeHandler_JSONOutput_NonJSONBodyErrors(t *testing.T) {
+	srv := newTestGraphQLServer(t, "<html>502 Bad Gateway</html>")
+
+	result := callTestHandler(t, srv.URL, "json")
+	if !result.IsError {
+		t.Fatalf("expected an error result for a non-JSON body in json mode, got: %s", resultText(t, result))
+	}
+}
@@ -0,0 +1,389 @@
+package forge
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// introspectionQuery is the standard GraphQL introspection query, trimmed to the fields
+// ForgeFromIntrospection needs: root operation types, every named type's fields/args/inputFields/
+// enumValues, and enough of the NON_NULL/LIST wrapper chain to reconstruct SDL type strings.
+const introspectionQuery = `query IntrospectionQuery {
+  __schema {
+    queryType { name }
+    mutationType { name }
+    types {
+      kind
+      name
+      description
+      fields(includeDeprecated: true) {
+        name
+        description
+        args {
+          name
+          description
+          type { ...TypeRef }
+        }
+        type { ...TypeRef }
+      }
+      inputFields {
+        name
+        description
+        type { ...TypeRef }
+      }
+      enumValues(includeDeprecated: true) {
+        name
+      }
+      possibleTypes {
+        name
+      }
+    }
+  }
+}
+
+fragment TypeRef on __Type {
+  kind
+  name
+  ofType {
+    kind
+    name
+    ofType {
+      kind
+      name
+      ofType {
+        kind
+        name
+        ofType {
+          kind
+          name
+        }
+      }
+    }
+  }
+}`
+
+type introspectionSchema struct {
+	QueryType    *introspectionNamedRef `json:"queryType"`
+	MutationType *introspectionNamedRef `json:"mutationType"`
+	Types        []introspectionType    `json:"types"`
+}
+
+type introspectionNamedRef struct {
+	Name string `json:"name"`
+}
+
+type introspectionType struct {
+	Kind          string                    `json:"kind"`
+	Name          string                    `json:"name"`
+	Description   string                    `json:"description"`
+	Fields        []introspectionField      `json:"fields"`
+	InputFields   []introspectionInputValue `json:"inputFields"`
+	EnumValues    []introspectionEnumValue  `json:"enumValues"`
+	PossibleTypes []introspectionNamedRef   `json:"possibleTypes"` // UNION/INTERFACE member types
+}
+
+type introspectionField struct {
+	Name        string                    `json:"name"`
+	Description string                    `json:"description"`
+	Args        []introspectionInputValue `json:"args"`
+	Type        introspectionTypeWrapper  `json:"type"`
+}
+
+type introspectionInputValue struct {
+	Name        string                   `json:"name"`
+	Description string                   `json:"description"`
+	Type        introspectionTypeWrapper `json:"type"`
+}
+
+type introspectionEnumValue struct {
+	Name string `json:"name"`
+}
+
+// introspectionTypeWrapper models a GraphQL type reference, including its NON_NULL/LIST wrappers
+type introspectionTypeWrapper struct {
+	Kind   string                    `json:"kind"`
+	Name   string                    `json:"name"`
+	OfType *introspectionTypeWrapper `json:"ofType"`
+}
+
+// ForgeFromIntrospection queries cfg.URL's GraphQL schema via introspection and writes one YAML
+// tool file per Query and Mutation field into configDir, so onboarding a new GraphQL API starts
+// from a generated baseline instead of a day of hand-written YAML.
+func ForgeFromIntrospection(cfg *ForgeConfig, configDir string, isDebug bool) error {
+	token := ""
+	if cfg.TokenCommand != "" {
+		raw, _, err := runTokenCommand(BackendConfig{TokenCommand: cfg.TokenCommand, Env: cfg.Env, EnvPassthrough: cfg.EnvPassthrough}, isDebug)
+		if err != nil {
+			return fmt.Errorf("token_command failed: %w", err)
+		}
+		token = "Bearer " + raw
+	}
+
+	httpClient := &http.Client{Timeout: cfg.RequestTimeoutDuration()}
+	body, status, err := ExecuteGraphQLWithRetry(context.Background(), httpClient, cfg.URL, introspectionQuery, nil, token, nil, cfg.Retry, isDebug)
+	if err != nil {
+		return fmt.Errorf("introspection request failed: %w", err)
+	}
+	if status != http.StatusOK {
+		return fmt.Errorf("introspection request failed: unexpected status %d", status)
+	}
+
+	var gqlResp GraphqlResponse
+	if err := json.Unmarshal(body, &gqlResp); err != nil {
+		return fmt.Errorf("parsing introspection response: %w", err)
+	}
+	if len(gqlResp.Errors) > 0 {
+		return fmt.Errorf("introspection query failed: %s", formatGraphqlErrors(gqlResp.Errors))
+	}
+
+	var wrapper struct {
+		Schema introspectionSchema `json:"__schema"`
+	}
+	if err := json.Unmarshal(gqlResp.Data, &wrapper); err != nil {
+		return fmt.Errorf("parsing __schema: %w", err)
+	}
+
+	typesByName := make(map[string]introspectionType, len(wrapper.Schema.Types))
+	for _, t := range wrapper.Schema.Types {
+		typesByName[t.Name] = t
+	}
+
+	if wrapper.Schema.QueryType != nil {
+		if err := writeToolFiles(configDir, "query", wrapper.Schema.QueryType.Name, typesByName, true); err != nil {
+			return err
+		}
+	}
+	if wrapper.Schema.MutationType != nil {
+		if err := writeToolFiles(configDir, "mutation", wrapper.Schema.MutationType.Name, typesByName, false); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeToolFiles emits one YAML tool file per field of the root operation type named rootTypeName
+func writeToolFiles(configDir, opKeyword, rootTypeName string, typesByName map[string]introspectionType, readOnly bool) error {
+	root, ok := typesByName[rootTypeName]
+	if !ok {
+		return nil
+	}
+
+	readOnlyHint := readOnly
+	destructiveHint := !readOnly
+
+	for _, field := range root.Fields {
+		tcfg := ToolConfig{
+			Name:        field.Name,
+			Description: field.Description,
+			Query:       buildOperation(opKeyword, field, typesByName),
+			Annotations: ToolAnnotations{
+				ReadOnlyHint:    &readOnlyHint,
+				DestructiveHint: &destructiveHint,
+			},
+		}
+		for _, arg := range field.Args {
+			tcfg.Inputs = append(tcfg.Inputs, buildToolInput(arg.Name, arg.Description, arg.Type, typesByName))
+		}
+
+		data, err := yaml.Marshal(&tcfg)
+		if err != nil {
+			return fmt.Errorf("marshal tool %q: %w", field.Name, err)
+		}
+		if err := os.WriteFile(filepath.Join(configDir, field.Name+".yaml"), data, 0644); err != nil {
+			return fmt.Errorf("write tool %q: %w", field.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// resolvedType is the named type at the bottom of a NON_NULL/LIST wrapper chain
+type resolvedType struct {
+	Required bool
+	List     bool
+	Kind     string
+	Name     string
+}
+
+func resolveTypeWrapper(t introspectionTypeWrapper) resolvedType {
+	var rt resolvedType
+	cur := &t
+	for cur != nil {
+		switch cur.Kind {
+		case "NON_NULL":
+			if !rt.List {
+				rt.Required = true
+			}
+			cur = cur.OfType
+		case "LIST":
+			rt.List = true
+			cur = cur.OfType
+		default:
+			rt.Kind = cur.Kind
+			rt.Name = cur.Name
+			cur = nil
+		}
+	}
+	return rt
+}
+
+// typeSDL renders a GraphQL type reference back into its SDL type string, e.g. "[ID!]!"
+func typeSDL(t introspectionTypeWrapper) string {
+	switch t.Kind {
+	case "NON_NULL":
+		return typeSDL(*t.OfType) + "!"
+	case "LIST":
+		return "[" + typeSDL(*t.OfType) + "]"
+	default:
+		return t.Name
+	}
+}
+
+// scalarToToolType maps a GraphQL scalar name to a ToolInput.Type
+func scalarToToolType(name string) string {
+	switch name {
+	case "Int", "Float":
+		return "number"
+	case "Boolean":
+		return "boolean"
+	default: // String, ID, and custom scalars
+		return "string"
+	}
+}
+
+// buildNamedToolInput builds the ToolInput fields that describe the named type at the bottom of a
+// wrapper chain (everything except Name/Description/Required, which the caller fills in)
+func buildNamedToolInput(kind, name string, typesByName map[string]introspectionType) ToolInput {
+	switch kind {
+	case "ENUM":
+		t := typesByName[name]
+		values := make([]string, 0, len(t.EnumValues))
+		for _, v := range t.EnumValues {
+			values = append(values, v.Name)
+		}
+		return ToolInput{Type: "enum", Enum: values}
+	case "INPUT_OBJECT":
+		t := typesByName[name]
+		props := make(map[string]ToolInput, len(t.InputFields))
+		for _, f := range t.InputFields {
+			props[f.Name] = buildToolInput(f.Name, f.Description, f.Type, typesByName)
+		}
+		return ToolInput{Type: "object", Properties: props}
+	default: // SCALAR
+		return ToolInput{Type: scalarToToolType(name)}
+	}
+}
+
+// buildToolInput converts a GraphQL argument/input field into a ToolInput, honoring non-null for Required
+func buildToolInput(name, description string, t introspectionTypeWrapper, typesByName map[string]introspectionType) ToolInput {
+	rt := resolveTypeWrapper(t)
+
+	if rt.List {
+		item := buildNamedToolInput(rt.Kind, rt.Name, typesByName)
+		return ToolInput{
+			Name:        name,
+			Description: description,
+			Required:    rt.Required,
+			Type:        "array",
+			Items:       &ToolInputItems{Type: item.Type, Properties: item.Properties},
+		}
+	}
+
+	inp := buildNamedToolInput(rt.Kind, rt.Name, typesByName)
+	inp.Name = name
+	inp.Description = description
+	inp.Required = rt.Required
+	return inp
+}
+
+// buildSelection renders a default projection for typeName: every scalar/enum field, plus one
+// level of nested object fields' own scalars, to keep generated queries from running away in depth.
+// A UNION (and, since interfaces may hide fields behind their implementors, an INTERFACE) also gets
+// an "... on Member { ... }" inline fragment per possibleTypes entry. Every GraphQL selection set
+// must be non-empty, so a type with no fields and no possibleTypes (or an unresolvable typeName)
+// falls back to "__typename" instead of emitting an empty "{ }".
+func buildSelection(typeName string, typesByName map[string]introspectionType, depth int) string {
+	t, ok := typesByName[typeName]
+	if !ok {
+		return "__typename"
+	}
+
+	var lines []string
+	for _, f := range t.Fields {
+		rt := resolveTypeWrapper(f.Type)
+		switch rt.Kind {
+		case "OBJECT", "INTERFACE", "UNION":
+			if depth == 0 {
+				if nested := buildSelection(rt.Name, typesByName, depth+1); nested != "" {
+					lines = append(lines, fmt.Sprintf("%s { %s }", f.Name, nested))
+				}
+			}
+		default:
+			lines = append(lines, f.Name)
+		}
+	}
+
+	if t.Kind == "UNION" || t.Kind == "INTERFACE" {
+		lines = append(lines, "__typename")
+		for _, pt := range t.PossibleTypes {
+			if nested := buildSelection(pt.Name, typesByName, depth+1); nested != "" {
+				lines = append(lines, fmt.Sprintf("... on %s { %s }", pt.Name, nested))
+			}
+		}
+	}
+
+	if len(lines) == 0 {
+		return "__typename"
+	}
+	return strings.Join(lines, " ")
+}
+
+// buildOperation renders a complete query/mutation document for field, including variable
+// declarations, the call with its arguments, and a default selection set for object-typed results
+func buildOperation(opKeyword string, field introspectionField, typesByName map[string]introspectionType) string {
+	varDecls := make([]string, 0, len(field.Args))
+	callArgs := make([]string, 0, len(field.Args))
+	for _, a := range field.Args {
+		varDecls = append(varDecls, fmt.Sprintf("$%s: %s", a.Name, typeSDL(a.Type)))
+		callArgs = append(callArgs, fmt.Sprintf("%s: $%s", a.Name, a.Name))
+	}
+
+	rt := resolveTypeWrapper(field.Type)
+	selection := ""
+	switch rt.Kind {
+	case "OBJECT", "INTERFACE", "UNION":
+		selection = buildSelection(rt.Name, typesByName, 0)
+	}
+
+	var sb strings.Builder
+	sb.WriteString(opKeyword)
+	sb.WriteString(" ")
+	sb.WriteString(field.Name)
+	if len(varDecls) > 0 {
+		sb.WriteString("(")
+		sb.WriteString(strings.Join(varDecls, ", "))
+		sb.WriteString(")")
+	}
+	sb.WriteString(" {\n  ")
+	sb.WriteString(field.Name)
+	if len(callArgs) > 0 {
+		sb.WriteString("(")
+		sb.WriteString(strings.Join(callArgs, ", "))
+		sb.WriteString(")")
+	}
+	if selection != "" {
+		sb.WriteString(" {\n    ")
+		sb.WriteString(selection)
+		sb.WriteString("\n  }")
+	}
+	sb.WriteString("\n}\n")
+	return sb.String()
+}
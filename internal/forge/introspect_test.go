@@ -0,0 +1,181 @@
+package forge
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// nonNullScalar builds the introspection wrapper for a "Name!" scalar type reference.
+func nonNullScalar(name string) introspectionTypeWrapper {
+	return introspectionTypeWrapper{Kind: "NON_NULL", OfType: &introspectionTypeWrapper{Kind: "SCALAR", Name: name}}
+}
+
+// searchSchemaTypes returns a small fixture: a Query.search(q: String!) field returning the SearchResult
+// union, whose members are the Article and User object types.
+func searchSchemaTypes() map[string]introspectionType {
+	return map[string]introspectionType{
+		"Query": {
+			Kind: "OBJECT",
+			Name: "Query",
+			Fields: []introspectionField{
+				{
+					Name: "search",
+					Args: []introspectionInputValue{
+						{Name: "q", Type: nonNullScalar("String")},
+					},
+					Type: introspectionTypeWrapper{Kind: "UNION", Name: "SearchResult"},
+				},
+			},
+		},
+		"SearchResult": {
+			Kind:          "UNION",
+			Name:          "SearchResult",
+			PossibleTypes: []introspectionNamedRef{{Name: "Article"}, {Name: "User"}},
+		},
+		"Article": {
+			Kind: "OBJECT",
+			Name: "Article",
+			Fields: []introspectionField{
+				{Name: "id", Type: nonNullScalar("ID")},
+				{Name: "title", Type: introspectionTypeWrapper{Kind: "SCALAR", Name: "String"}},
+			},
+		},
+		"User": {
+			Kind: "OBJECT",
+			Name: "User",
+			Fields: []introspectionField{
+				{Name: "id", Type: nonNullScalar("ID")},
+				{Name: "name", Type: introspectionTypeWrapper{Kind: "SCALAR", Name: "String"}},
+			},
+		},
+	}
+}
+
+func TestBuildSelection_Union(t *testing.T) {
+	got := buildSelection("SearchResult", searchSchemaTypes(), 0)
+	for _, want := range []string{"__typename", "... on Article", "id", "title", "... on User", "name"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("buildSelection(SearchResult) = %q, missing %q", got, want)
+		}
+	}
+}
+
+func TestBuildSelection_EmptyObjectFallsBackToTypename(t *testing.T) {
+	typesByName := map[string]introspectionType{"Empty": {Kind: "OBJECT", Name: "Empty"}}
+	if got := buildSelection("Empty", typesByName, 0); got != "__typename" {
+		t.Errorf("buildSelection(Empty) = %q, want __typename", got)
+	}
+}
+
+func TestBuildSelection_UnknownTypeFallsBackToTypename(t *testing.T) {
+	if got := buildSelection("Missing", map[string]introspectionType{}, 0); got != "__typename" {
+		t.Errorf("buildSelection(Missing) = %q, want __typename", got)
+	}
+}
+
+func TestBuildOperation_UnionReturnTypeHasNonEmptySelectionSet(t *testing.T) {
+	typesByName := searchSchemaTypes()
+	doc := buildOperation("query", typesByName["Query"].Fields[0], typesByName)
+
+	if !strings.Contains(doc, "__typename") {
+		t.Errorf("union-returning operation has no selection set:\n%s", doc)
+	}
+	if strings.Contains(doc, "search(q: $q)\n}") {
+		t.Errorf("operation emitted a field with no selection set:\n%s", doc)
+	}
+}
+
+func TestResolveTypeWrapper_NonNullListOfNonNullScalar(t *testing.T) {
+	// [String!]!
+	wrapper := introspectionTypeWrapper{
+		Kind: "NON_NULL",
+		OfType: &introspectionTypeWrapper{
+			Kind:   "LIST",
+			OfType: &introspectionTypeWrapper{Kind: "NON_NULL", OfType: &introspectionTypeWrapper{Kind: "SCALAR", Name: "String"}},
+		},
+	}
+
+	rt := resolveTypeWrapper(wrapper)
+	if !rt.Required || !rt.List || rt.Kind != "SCALAR" || rt.Name != "String" {
+		t.Errorf("resolveTypeWrapper([String!]!) = %+v, want Required=true List=true Kind=SCALAR Name=String", rt)
+	}
+	if got := typeSDL(wrapper); got != "[String!]!" {
+		t.Errorf("typeSDL([String!]!) = %q", got)
+	}
+}
+
+func TestBuildToolInput_ListOfInputObjectThreadsProperties(t *testing.T) {
+	typesByName := map[string]introspectionType{
+		"Filter": {
+			Kind: "INPUT_OBJECT",
+			Name: "Filter",
+			InputFields: []introspectionInputValue{
+				{Name: "name", Type: introspectionTypeWrapper{Kind: "SCALAR", Name: "String"}},
+			},
+		},
+	}
+	argType := introspectionTypeWrapper{Kind: "LIST", OfType: &introspectionTypeWrapper{Kind: "INPUT_OBJECT", Name: "Filter"}}
+
+	inp := buildToolInput("filters", "", argType, typesByName)
+
+	if inp.Type != "array" || inp.Items == nil {
+		t.Fatalf("buildToolInput(list of Filter) = %+v, want an array with items", inp)
+	}
+	if inp.Items.Type != "object" {
+		t.Errorf("items.Type = %q, want object", inp.Items.Type)
+	}
+	if _, ok := inp.Items.Properties["name"]; !ok {
+		t.Errorf("items.Properties = %+v, want a \"name\" property threaded from Filter's InputFields", inp.Items.Properties)
+	}
+}
+
+func TestForgeFromIntrospection_UnionFieldYieldsLoadableTool(t *testing.T) {
+	schemaData, err := json.Marshal(struct {
+		QueryType *introspectionNamedRef `json:"queryType"`
+		Types     []introspectionType    `json:"types"`
+	}{
+		QueryType: &introspectionNamedRef{Name: "Query"},
+		Types: []introspectionType{
+			searchSchemaTypes()["Query"],
+			searchSchemaTypes()["SearchResult"],
+			searchSchemaTypes()["Article"],
+			searchSchemaTypes()["User"],
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	respBody, err := json.Marshal(struct {
+		Data struct {
+			Schema json.RawMessage `json:"__schema"`
+		} `json:"data"`
+	}{Data: struct {
+		Schema json.RawMessage `json:"__schema"`
+	}{Schema: schemaData}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(respBody)
+	}))
+	t.Cleanup(srv.Close)
+
+	configDir := t.TempDir()
+	if err := ForgeFromIntrospection(&ForgeConfig{URL: srv.URL}, configDir, false); err != nil {
+		t.Fatalf("ForgeFromIntrospection: %v", err)
+	}
+
+	tcfg, err := LoadToolConfig(filepath.Join(configDir, "search.yaml"))
+	if err != nil {
+		t.Fatalf("generated tool file failed to load: %v", err)
+	}
+	if !strings.Contains(tcfg.Query, "__typename") {
+		t.Errorf("generated query has no selection set for the union return type:\n%s", tcfg.Query)
+	}
+}
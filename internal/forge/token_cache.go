@@ -0,0 +1,137 @@
+package forge
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// tokenCommandResult is the optional JSON shape a token_command may print instead of a raw token
+// string, letting the command itself drive the cache TTL.
+type tokenCommandResult struct {
+	Token     string `json:"token"`
+	ExpiresAt string `json:"expires_at"`
+}
+
+// parseTokenOutput interprets a token_command's stdout, returning the bearer token and, if the
+// command printed a JSON {"token":...,"expires_at":...} object, the remaining time until expiry.
+// A ttl of 0 means the caller's default TTL should be used instead.
+func parseTokenOutput(raw []byte) (token string, ttl time.Duration, err error) {
+	trimmed := bytes.TrimSpace(raw)
+
+	var structured tokenCommandResult
+	if json.Unmarshal(trimmed, &structured) == nil && structured.Token != "" {
+		if structured.ExpiresAt != "" {
+			if expiresAt, perr := time.Parse(time.RFC3339, structured.ExpiresAt); perr == nil {
+				return structured.Token, time.Until(expiresAt), nil
+			}
+		}
+		return structured.Token, 0, nil
+	}
+
+	return string(trimmed), 0, nil
+}
+
+// tokenCacheKey derives a cache key from the parts of a BackendConfig that affect token_command's
+// output, so two tools sharing a backend share a cache entry.
+func tokenCacheKey(b BackendConfig) string {
+	keys := make([]string, 0, len(b.Env))
+	for k := range b.Env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var sb strings.Builder
+	sb.WriteString(b.TokenCommand)
+	sb.WriteString("|passthrough=")
+	sb.WriteString(strconv.FormatBool(b.EnvPassthrough))
+	for _, k := range keys {
+		sb.WriteString("|")
+		sb.WriteString(k)
+		sb.WriteString("=")
+		sb.WriteString(b.Env[k])
+	}
+	return sb.String()
+}
+
+// tokenCall represents an in-flight token_command execution that other callers can wait on instead
+// of starting a redundant execution of their own.
+type tokenCall struct {
+	wg    sync.WaitGroup
+	token string
+	err   error
+}
+
+// TokenCache caches token_command output per cache key with a TTL, coalescing concurrent cache
+// misses for the same key into a single command execution.
+type TokenCache struct {
+	defaultTTL time.Duration
+
+	mu      sync.Mutex
+	entries map[string]tokenCacheEntry
+	calls   map[string]*tokenCall
+}
+
+type tokenCacheEntry struct {
+	token     string
+	expiresAt time.Time
+}
+
+// NewTokenCache creates a TokenCache that reuses a token for defaultTTL when the token_command
+// doesn't report its own expiry.
+func NewTokenCache(defaultTTL time.Duration) *TokenCache {
+	return &TokenCache{
+		defaultTTL: defaultTTL,
+		entries:    make(map[string]tokenCacheEntry),
+		calls:      make(map[string]*tokenCall),
+	}
+}
+
+// Get returns the cached token for key if it hasn't expired, otherwise it runs fn to obtain a
+// fresh one. Concurrent Get calls for the same key share a single fn execution.
+func (c *TokenCache) Get(key string, fn func() (token string, ttl time.Duration, err error)) (string, error) {
+	c.mu.Lock()
+	if e, ok := c.entries[key]; ok && time.Now().Before(e.expiresAt) {
+		c.mu.Unlock()
+		return e.token, nil
+	}
+	if call, ok := c.calls[key]; ok {
+		c.mu.Unlock()
+		call.wg.Wait()
+		return call.token, call.err
+	}
+
+	call := &tokenCall{}
+	call.wg.Add(1)
+	c.calls[key] = call
+	c.mu.Unlock()
+
+	token, ttl, err := fn()
+
+	c.mu.Lock()
+	delete(c.calls, key)
+	if err == nil {
+		effectiveTTL := ttl
+		if effectiveTTL == 0 {
+			effectiveTTL = c.defaultTTL
+		}
+		c.entries[key] = tokenCacheEntry{token: token, expiresAt: time.Now().Add(effectiveTTL)}
+	}
+	c.mu.Unlock()
+
+	call.token, call.err = token, err
+	call.wg.Done()
+
+	return token, err
+}
+
+// Invalidate discards the cached token for key, forcing the next Get to run fn again.
+func (c *TokenCache) Invalidate(key string) {
+	c.mu.Lock()
+	delete(c.entries, key)
+	c.mu.Unlock()
+}
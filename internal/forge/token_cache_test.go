@@ -0,0 +1,128 @@
+package forge
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestTokenCache_ConcurrentGetCoalescesIntoOneCall(t *testing.T) {
+	cache := NewTokenCache(time.Minute)
+
+	var calls int32
+	fn := func() (string, time.Duration, error) {
+		atomic.AddInt32(&calls, 1)
+		time.Sleep(20 * time.Millisecond)
+		return "tok", 0, nil
+	}
+
+	const n = 20
+	var wg sync.WaitGroup
+	results := make([]string, n)
+	wg.Add(n)
+	for i := 0; i < n; i++ {
+		go func(i int) {
+			defer wg.Done()
+			token, err := cache.Get("key", fn)
+			if err != nil {
+				t.Errorf("Get: %v", err)
+			}
+			results[i] = token
+		}(i)
+	}
+	wg.Wait()
+
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Errorf("fn ran %d times, want exactly 1", got)
+	}
+	for i, r := range results {
+		if r != "tok" {
+			t.Errorf("results[%d] = %q, want %q", i, r, "tok")
+		}
+	}
+}
+
+func TestTokenCache_ExpiresAfterTTL(t *testing.T) {
+	cache := NewTokenCache(10 * time.Millisecond)
+
+	var calls int32
+	fn := func() (string, time.Duration, error) {
+		n := atomic.AddInt32(&calls, 1)
+		return fmt.Sprintf("tok-%d", n), 0, nil
+	}
+
+	first, err := cache.Get("key", fn)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if first != "tok-1" {
+		t.Fatalf("first = %q, want tok-1", first)
+	}
+
+	if again, err := cache.Get("key", fn); err != nil || again != "tok-1" {
+		t.Errorf("Get before expiry = (%q, %v), want (tok-1, nil)", again, err)
+	}
+
+	time.Sleep(20 * time.Millisecond)
+
+	second, err := cache.Get("key", fn)
+	if err != nil {
+		t.Fatalf("Get after expiry: %v", err)
+	}
+	if second != "tok-2" {
+		t.Errorf("second = %q, want tok-2 (fn should re-run after TTL expiry)", second)
+	}
+	if got := atomic.LoadInt32(&calls); got != 2 {
+		t.Errorf("fn ran %d times, want exactly 2", got)
+	}
+}
+
+func TestTokenCache_Invalidate(t *testing.T) {
+	cache := NewTokenCache(time.Minute)
+
+	var calls int32
+	fn := func() (string, time.Duration, error) {
+		n := atomic.AddInt32(&calls, 1)
+		return fmt.Sprintf("tok-%d", n), 0, nil
+	}
+
+	if _, err := cache.Get("key", fn); err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	cache.Invalidate("key")
+
+	second, err := cache.Get("key", fn)
+	if err != nil {
+		t.Fatalf("Get after Invalidate: %v", err)
+	}
+	if second != "tok-2" {
+		t.Errorf("second = %q, want tok-2 (fn should re-run after Invalidate)", second)
+	}
+	if got := atomic.LoadInt32(&calls); got != 2 {
+		t.Errorf("fn ran %d times, want exactly 2", got)
+	}
+}
+
+func TestTokenCache_UsesCommandReportedTTLOverDefault(t *testing.T) {
+	cache := NewTokenCache(time.Hour)
+
+	var calls int32
+	fn := func() (string, time.Duration, error) {
+		atomic.AddInt32(&calls, 1)
+		return "tok", 10 * time.Millisecond, nil
+	}
+
+	if _, err := cache.Get("key", fn); err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	time.Sleep(20 * time.Millisecond)
+	if _, err := cache.Get("key", fn); err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+
+	if got := atomic.LoadInt32(&calls); got != 2 {
+		t.Errorf("fn ran %d times, want exactly 2 (command's own ttl should override the cache default)", got)
+	}
+}
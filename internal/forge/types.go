@@ -5,17 +5,124 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
 
+// defaultTokenTTL is how long a cached token_command result is reused when neither ForgeConfig.TokenTTL
+// nor the command's own {"expires_at":...} output says otherwise.
+const defaultTokenTTL = 5 * time.Minute
+
+// defaultRequestTimeout bounds how long a GraphQL request may run when ForgeConfig.RequestTimeout is unset.
+const defaultRequestTimeout = 30 * time.Second
+
 // ForgeConfig holds global server settings
 type ForgeConfig struct {
-	Name           string            `yaml:"name"`
+	Name           string                   `yaml:"name"`
+	URL            string                   `yaml:"url"`
+	TokenCommand   string                   `yaml:"token_command"`
+	Env            map[string]string        `yaml:"env,omitempty"`
+	EnvPassthrough bool                     `yaml:"env_passthrough,omitempty"`
+	Headers        map[string]string        `yaml:"headers,omitempty"`         // extra HTTP headers sent with every request to the default backend
+	Output         string                   `yaml:"output,omitempty"`          // forge-wide default for "raw" (default), "json", or "toon"; overridden per-tool by ToolConfig.Output
+	Backends       map[string]BackendConfig `yaml:"backends,omitempty"`        // named upstreams a tool can select via ToolConfig.Backend; the top-level URL/TokenCommand/Env/EnvPassthrough/Headers fields above remain the implicit default backend, used by tools whose Backend is empty
+	TokenTTL       string                   `yaml:"token_ttl,omitempty"`       // Go duration string for how long a cached token_command result is reused (default "5m")
+	RequestTimeout string                   `yaml:"request_timeout,omitempty"` // Go duration string for the GraphQL HTTP client's request timeout (default "30s")
+	Retry          RetryPolicy              `yaml:"retry,omitempty"`           // retry/backoff behavior for transient GraphQL request failures
+}
+
+// TokenTTLDuration parses TokenTTL, falling back to defaultTokenTTL if it is unset or invalid.
+func (c *ForgeConfig) TokenTTLDuration() time.Duration {
+	if c.TokenTTL == "" {
+		return defaultTokenTTL
+	}
+	d, err := time.ParseDuration(c.TokenTTL)
+	if err != nil {
+		return defaultTokenTTL
+	}
+	return d
+}
+
+// RequestTimeoutDuration parses RequestTimeout, falling back to defaultRequestTimeout if it is unset or invalid.
+func (c *ForgeConfig) RequestTimeoutDuration() time.Duration {
+	if c.RequestTimeout == "" {
+		return defaultRequestTimeout
+	}
+	d, err := time.ParseDuration(c.RequestTimeout)
+	if err != nil {
+		return defaultRequestTimeout
+	}
+	return d
+}
+
+// defaultMaxAttempts, defaultBaseDelay, and defaultMaxDelay are RetryPolicy's defaults when unset.
+const (
+	defaultMaxAttempts = 1
+	defaultBaseDelay   = 200 * time.Millisecond
+	defaultMaxDelay    = 5 * time.Second
+)
+
+// defaultRetryableStatusCodes are retried when RetryPolicy.RetryableStatus is unset.
+var defaultRetryableStatusCodes = []int{429, 500, 502, 503, 504}
+
+// RetryPolicy configures ExecuteGraphQLWithRetry's handling of transient GraphQL request failures
+type RetryPolicy struct {
+	MaxAttempts     int    `yaml:"max_attempts,omitempty"`     // total attempts including the first; default 1 (no retries)
+	BaseDelay       string `yaml:"base_delay,omitempty"`       // Go duration string, default "200ms"
+	MaxDelay        string `yaml:"max_delay,omitempty"`        // Go duration string, default "5s"
+	Jitter          bool   `yaml:"jitter,omitempty"`           // randomize each backoff delay between 0.5x and 1.5x
+	RetryableStatus []int  `yaml:"retryable_status,omitempty"` // HTTP status codes to retry; default 429, 500, 502, 503, 504
+	RetryThrottled  bool   `yaml:"retry_throttled,omitempty"`  // also retry when a GraphQL error's extensions.code is "THROTTLED"
+}
+
+// MaxAttemptsOrDefault returns MaxAttempts, falling back to defaultMaxAttempts if unset or invalid.
+func (p RetryPolicy) MaxAttemptsOrDefault() int {
+	if p.MaxAttempts <= 0 {
+		return defaultMaxAttempts
+	}
+	return p.MaxAttempts
+}
+
+// BaseDelayDuration parses BaseDelay, falling back to defaultBaseDelay if it is unset or invalid.
+func (p RetryPolicy) BaseDelayDuration() time.Duration {
+	if p.BaseDelay == "" {
+		return defaultBaseDelay
+	}
+	d, err := time.ParseDuration(p.BaseDelay)
+	if err != nil {
+		return defaultBaseDelay
+	}
+	return d
+}
+
+// MaxDelayDuration parses MaxDelay, falling back to defaultMaxDelay if it is unset or invalid.
+func (p RetryPolicy) MaxDelayDuration() time.Duration {
+	if p.MaxDelay == "" {
+		return defaultMaxDelay
+	}
+	d, err := time.ParseDuration(p.MaxDelay)
+	if err != nil {
+		return defaultMaxDelay
+	}
+	return d
+}
+
+// RetryableStatusCodes returns RetryableStatus, falling back to defaultRetryableStatusCodes if unset.
+func (p RetryPolicy) RetryableStatusCodes() []int {
+	if len(p.RetryableStatus) == 0 {
+		return defaultRetryableStatusCodes
+	}
+	return p.RetryableStatus
+}
+
+// BackendConfig defines one named upstream GraphQL endpoint a tool can target via ToolConfig.Backend
+type BackendConfig struct {
 	URL            string            `yaml:"url"`
-	TokenCommand   string            `yaml:"token_command"`
+	TokenCommand   string            `yaml:"token_command,omitempty"`
 	Env            map[string]string `yaml:"env,omitempty"`
 	EnvPassthrough bool              `yaml:"env_passthrough,omitempty"`
+	Headers        map[string]string `yaml:"headers,omitempty"`
 }
 
 // LoadForgeConfig loads ForgeConfig from the given file path
@@ -33,17 +140,31 @@ func LoadForgeConfig(path string) (*ForgeConfig, error) {
 
 // ToolConfig holds one tool's YAML definition
 type ToolConfig struct {
-	Name        string `yaml:"name"`
-	Description string `yaml:"description"`
-	Query       string `yaml:"query"`
-	Inputs      []struct {
-		Name        string `yaml:"name"`
-		Type        string `yaml:"type"` // "string" or "number"
-		Description string `yaml:"description"`
-		Required    bool   `yaml:"required"`
-	} `yaml:"inputs"`
+	Name        string          `yaml:"name"`
+	Description string          `yaml:"description"`
+	Query       string          `yaml:"query"`
+	Inputs      []ToolInput     `yaml:"inputs"`
 	Annotations ToolAnnotations `yaml:"annotations,omitempty"`
-	Output      string          `yaml:"output,omitempty"` // "raw" (default), "json", or "toon"
+	Output      string          `yaml:"output,omitempty"`  // "raw" (default), "json", or "toon"; overrides ForgeConfig.Output
+	Backend     string          `yaml:"backend,omitempty"` // name of the ForgeConfig.Backends entry to use; empty selects the default backend
+}
+
+// ToolInput describes one argument a tool accepts, how it's exposed to the MCP client, and how
+// it's coerced into the GraphQL variables map.
+type ToolInput struct {
+	Name        string               `yaml:"name"`
+	Type        string               `yaml:"type"` // "string", "number", "boolean", "enum", "array", or "object"
+	Description string               `yaml:"description"`
+	Required    bool                 `yaml:"required"`
+	Enum        []string             `yaml:"enum,omitempty"`       // allowed values; required when Type is "enum"
+	Items       *ToolInputItems      `yaml:"items,omitempty"`      // element type; required when Type is "array"
+	Properties  map[string]ToolInput `yaml:"properties,omitempty"` // nested fields; used when Type is "object"
+}
+
+// ToolInputItems describes the element type of an "array" ToolInput
+type ToolInputItems struct {
+	Type       string               `yaml:"type"`
+	Properties map[string]ToolInput `yaml:"properties,omitempty"` // nested fields; used when Type is "object"
 }
 
 // ToolAnnotations defines the annotations for a tool
@@ -65,9 +186,45 @@ func LoadToolConfig(path string) (*ToolConfig, error) {
 	if err := yaml.Unmarshal(data, &cfg); err != nil {
 		return nil, fmt.Errorf("unmarshal ToolConfig: %w", err)
 	}
+	for _, inp := range cfg.Inputs {
+		if err := validateToolInput(inp); err != nil {
+			return nil, fmt.Errorf("invalid input %q: %w", inp.Name, err)
+		}
+	}
 	return &cfg, nil
 }
 
+// validateToolInput checks that inp is well-formed for its declared Type, so a misconfigured tool
+// fails at load time instead of being silently skipped or sent a malformed GraphQL variable.
+func validateToolInput(inp ToolInput) error {
+	switch inp.Type {
+	case "string", "number", "boolean":
+		// no extra fields required
+	case "enum":
+		if len(inp.Enum) == 0 {
+			return fmt.Errorf(`type "enum" requires a non-empty "enum" list`)
+		}
+	case "array":
+		if inp.Items == nil || inp.Items.Type == "" {
+			return fmt.Errorf(`type "array" requires "items.type"`)
+		}
+		for name, prop := range inp.Items.Properties {
+			if err := validateToolInput(prop); err != nil {
+				return fmt.Errorf("items property %q: %w", name, err)
+			}
+		}
+	case "object":
+		for name, prop := range inp.Properties {
+			if err := validateToolInput(prop); err != nil {
+				return fmt.Errorf("property %q: %w", name, err)
+			}
+		}
+	default:
+		return fmt.Errorf("unsupported type %q", inp.Type)
+	}
+	return nil
+}
+
 // AppConfig holds the parsed application configuration
 type AppConfig struct {
 	ConfigDir string
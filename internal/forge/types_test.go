@@ -0,0 +1,131 @@
+package forge
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestValidateToolInput(t *testing.T) {
+	tests := []struct {
+		name    string
+		inp     ToolInput
+		wantErr bool
+	}{
+		{name: "string", inp: ToolInput{Type: "string"}},
+		{name: "number", inp: ToolInput{Type: "number"}},
+		{name: "boolean", inp: ToolInput{Type: "boolean"}},
+		{name: "valid enum", inp: ToolInput{Type: "enum", Enum: []string{"A", "B"}}},
+		{name: "enum with no values", inp: ToolInput{Type: "enum"}, wantErr: true},
+		{name: "valid array", inp: ToolInput{Type: "array", Items: &ToolInputItems{Type: "string"}}},
+		{name: "array with no items", inp: ToolInput{Type: "array"}, wantErr: true},
+		{name: "array with untyped items", inp: ToolInput{Type: "array", Items: &ToolInputItems{}}, wantErr: true},
+		{
+			name: "array of objects with a valid item property",
+			inp: ToolInput{Type: "array", Items: &ToolInputItems{
+				Type:       "object",
+				Properties: map[string]ToolInput{"name": {Type: "string"}},
+			}},
+		},
+		{
+			name: "array of objects with an invalid item property",
+			inp: ToolInput{Type: "array", Items: &ToolInputItems{
+				Type:       "object",
+				Properties: map[string]ToolInput{"bad": {Type: "not-a-type"}},
+			}},
+			wantErr: true,
+		},
+		{
+			name: "valid object",
+			inp:  ToolInput{Type: "object", Properties: map[string]ToolInput{"name": {Type: "string"}}},
+		},
+		{
+			name:    "object with an invalid property",
+			inp:     ToolInput{Type: "object", Properties: map[string]ToolInput{"bad": {Type: "not-a-type"}}},
+			wantErr: true,
+		},
+		{name: "unsupported type", inp: ToolInput{Type: "not-a-type"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateToolInput(tt.inp)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateToolInput(%+v) error = %v, wantErr %v", tt.inp, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCoerceInputValue(t *testing.T) {
+	t.Run("whole-valued number becomes an int", func(t *testing.T) {
+		got := coerceInputValue(ToolInput{Type: "number"}, float64(3))
+		if _, ok := got.(int64); !ok {
+			t.Errorf("coerceInputValue(number, 3.0) = %#v (%T), want an int64", got, got)
+		}
+	})
+
+	t.Run("fractional number stays a float", func(t *testing.T) {
+		got := coerceInputValue(ToolInput{Type: "number"}, 3.5)
+		if got != 3.5 {
+			t.Errorf("coerceInputValue(number, 3.5) = %#v, want 3.5", got)
+		}
+	})
+
+	t.Run("array of numbers coerces each element", func(t *testing.T) {
+		inp := ToolInput{Type: "array", Items: &ToolInputItems{Type: "number"}}
+		got := coerceInputValue(inp, []interface{}{float64(1), float64(2)})
+		want := []interface{}{int64(1), int64(2)}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("coerceInputValue(array of number) = %#v, want %#v", got, want)
+		}
+	})
+
+	t.Run("array of objects coerces each element's properties", func(t *testing.T) {
+		inp := ToolInput{Type: "array", Items: &ToolInputItems{
+			Type:       "object",
+			Properties: map[string]ToolInput{"count": {Type: "number"}},
+		}}
+		got := coerceInputValue(inp, []interface{}{
+			map[string]interface{}{"count": float64(2)},
+		})
+		want := []interface{}{
+			map[string]interface{}{"count": int64(2)},
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("coerceInputValue(array of object) = %#v, want %#v", got, want)
+		}
+	})
+
+	t.Run("object coerces known properties and passes through unknown ones", func(t *testing.T) {
+		inp := ToolInput{Type: "object", Properties: map[string]ToolInput{"count": {Type: "number"}}}
+		got := coerceInputValue(inp, map[string]interface{}{"count": float64(4), "note": "hi"})
+		want := map[string]interface{}{"count": int64(4), "note": "hi"}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("coerceInputValue(object) = %#v, want %#v", got, want)
+		}
+	})
+}
+
+func TestToolInputOption_ArrayOfObjectsExposesNestedProperties(t *testing.T) {
+	inp := ToolInput{
+		Name: "filters",
+		Type: "array",
+		Items: &ToolInputItems{
+			Type:       "object",
+			Properties: map[string]ToolInput{"name": {Type: "string"}},
+		},
+	}
+
+	// toolInputOption must not panic on an array-of-objects input, and the schema it builds via
+	// toolInputSchema must carry the item properties through rather than collapsing to {"type":"object"}.
+	_ = toolInputOption(inp)
+
+	itemSchema := toolInputSchema(itemsToolInput(inp.Items))
+	props, ok := itemSchema["properties"].(map[string]any)
+	if !ok {
+		t.Fatalf("item schema = %#v, want a properties map", itemSchema)
+	}
+	if _, ok := props["name"]; !ok {
+		t.Errorf("item schema properties = %#v, missing \"name\"", props)
+	}
+}
@@ -18,10 +18,12 @@ func main() {
 	var httpAddr string
 	var forgeConfigFlag string
 	var forgeDebugFlag bool
+	var introspectFlag bool
 
 	flag.StringVar(&httpAddr, "http", "", "run HTTP streamable transport on the given address, e.g. 8080 (defaults to stdio if empty)")
 	flag.StringVar(&forgeConfigFlag, "forgeConfig", "", "path to the folder containing forge.yaml and tool definitions (overrides FORGE_CONFIG env)")
 	flag.BoolVar(&forgeDebugFlag, "forgeDebug", false, "enable debug logging (overrides FORGE_DEBUG env)")
+	flag.BoolVar(&introspectFlag, "introspect", false, "generate a YAML tool file per Query/Mutation field from the backend's GraphQL schema, then exit")
 
 	flag.Parse()
 
@@ -32,6 +34,14 @@ func main() {
 		os.Exit(1)
 	}
 
+	if introspectFlag {
+		if err := forge.ForgeFromIntrospection(appConfig.Config, appConfig.ConfigDir, appConfig.IsDebug); err != nil {
+			fmt.Fprintf(os.Stderr, "Error generating tools from introspection: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	// Setup logging based on debug mode
 	if appConfig.IsDebug {
 		log.SetOutput(os.Stderr)